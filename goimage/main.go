@@ -1,141 +1,24 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"image"
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
-	"strings"
-	"time"
-)
-
-// Supported image formats (formats supported by Go standard library)
-var supportedExts = map[string]bool{
-	".jpg":  true,
-	".jpeg": true,
-	".png":  true,
-	".gif":  true,
-}
-
-type ImageInfo struct {
-	Type   string
-	Width  int
-	Height int
-}
-
-// calculateGrayValue calculates the gray value (brightness) of an RGB color.
-// Uses the standard luminance formula: 0.299*R + 0.587*G + 0.114*B
-func calculateGrayValue(r, g, b uint32) int {
-	return int(0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8))
-}
-
-// normalizeGrayValue normalizes gray value to range 1-254 for single color images.
-func normalizeGrayValue(grayValue int) int {
-	if grayValue == 0 {
-		return 1
-	}
-	if grayValue == 255 {
-		return 254
-	}
-	return grayValue
-}
-
-// detectImageType detects the type of an image.
-func detectImageType(img image.Image) string {
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-
-	if width == 0 || height == 0 {
-		return "Mixed pixels"
-	}
-
-	// Get the first pixel as reference
-	firstPixel := img.At(bounds.Min.X, bounds.Min.Y)
-	firstR, firstG, firstB, _ := firstPixel.RGBA()
-	firstGray := calculateGrayValue(firstR, firstG, firstB)
-
-	// Check if all pixels are the same
-	allSame := true
-	allSameGray := true
-
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			pixel := img.At(x, y)
-			r, g, b, _ := pixel.RGBA()
-
-			// Check if pixel is identical to first pixel
-			if r != firstR || g != firstG || b != firstB {
-				allSame = false
-			}
-
-			// Check if pixel has same gray value
-			gray := calculateGrayValue(r, g, b)
-			if gray != firstGray {
-				allSameGray = false
-			}
-
-			// Early exit if we found different pixels
-			if !allSame && !allSameGray {
-				return "Mixed pixels"
-			}
-		}
-	}
-
-	if allSame {
-		// All pixels are identical
-		r8 := firstR >> 8
-		g8 := firstG >> 8
-		b8 := firstB >> 8
-
-		if r8 == 0 && g8 == 0 && b8 == 0 {
-			return "All black"
-		}
-		if r8 == 255 && g8 == 255 && b8 == 255 {
-			return "All white"
-		}
-		// Single color (not black or white)
-		return fmt.Sprintf("Single color (gray value: %d)", normalizeGrayValue(firstGray))
-	}
-
-	if allSameGray {
-		// All pixels have the same brightness but different colors
-		return fmt.Sprintf("Single color (gray value: %d)", normalizeGrayValue(firstGray))
-	}
-
-	return "Mixed pixels"
-}
-
-// processImage processes a single image and returns its information.
-func processImage(imagePath string) (*ImageInfo, error) {
-	file, err := os.Open(imagePath)
-	if err != nil {
-		return nil, fmt.Errorf("error opening file: %v", err)
-	}
-	defer file.Close()
 
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return nil, fmt.Errorf("error decoding image: %v", err)
-	}
+	"github.com/zihao-lin-appier/image-capture-result-error-detect/pkg/batch"
+	"github.com/zihao-lin-appier/image-capture-result-error-detect/pkg/detect"
 
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-	imageType := detectImageType(img)
-
-	return &ImageInfo{
-		Type:   imageType,
-		Width:  width,
-		Height: height,
-	}, nil
-}
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
 
-// getImageFiles gets all image files from the specified folder.
+// getImageFiles gets all image files from the specified folder, deciding
+// what's an image by content-sniffing each entry rather than trusting its
+// extension. This picks up renamed or extension-less image files too.
 func getImageFiles(folderPath string) ([]string, error) {
 	entries, err := os.ReadDir(folderPath)
 	if err != nil {
@@ -144,11 +27,12 @@ func getImageFiles(folderPath string) ([]string, error) {
 
 	var imageFiles []string
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			ext := strings.ToLower(filepath.Ext(entry.Name()))
-			if supportedExts[ext] {
-				imageFiles = append(imageFiles, filepath.Join(folderPath, entry.Name()))
-			}
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(folderPath, entry.Name())
+		if _, ok := detect.SniffFile(path); ok {
+			imageFiles = append(imageFiles, path)
 		}
 	}
 
@@ -156,47 +40,59 @@ func getImageFiles(folderPath string) ([]string, error) {
 	return imageFiles, nil
 }
 
-// processImages processes all images in the specified folder.
-func processImages(folderPath string) error {
+// newDetectOne adapts detect.DetectFile to batch.DetectFunc.
+func newDetectOne(opts detect.DetectOptions) batch.DetectFunc {
+	return func(path string) (batch.Info, error) {
+		info, err := detect.DetectFile(path, opts)
+		if err != nil {
+			return batch.Info{}, err
+		}
+		return batch.Info{
+			Type:     info.Type,
+			Width:    info.Width,
+			Height:   info.Height,
+			Blurhash: info.Blurhash,
+			PHash:    info.PHash,
+		}, nil
+	}
+}
+
+// processImages detects every image in folderPath using a bounded worker
+// pool and writes the results to stdout in the requested format.
+func processImages(folderPath string, workers int, format batch.Format, opts detect.DetectOptions) error {
 	imageFiles, err := getImageFiles(folderPath)
 	if err != nil {
 		return err
 	}
 
 	if len(imageFiles) == 0 {
-		fmt.Printf("No supported image files found in '%s'.\n", folderPath)
-		fmt.Printf("Supported formats: %s\n", strings.Join([]string{".jpg", ".jpeg", ".png", ".gif"}, ", "))
+		fmt.Printf("No decodable image files found in '%s'.\n", folderPath)
 		return nil
 	}
 
-	// Record start time
-	startTime := time.Now()
-
-	// Process each image
-	for _, imagePath := range imageFiles {
-		info, err := processImage(imagePath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error processing %s: %v\n", filepath.Base(imagePath), err)
-			continue
-		}
-		fmt.Printf("%s (%dx%d): %s\n", filepath.Base(imagePath), info.Width, info.Height, info.Type)
-	}
-
-	// Calculate and print elapsed time
-	elapsed := time.Since(startTime)
-	fmt.Printf("Total processing time: %.2f milliseconds\n", float64(elapsed.Nanoseconds())/1e6)
-
-	return nil
+	results, summary := batch.Run(imageFiles, workers, newDetectOne(opts))
+	return batch.Write(os.Stdout, format, results, summary)
 }
 
 func main() {
+	workers := flag.Int("j", runtime.NumCPU(), "number of images to detect concurrently")
+	format := flag.String("format", "text", "output format: text, json, csv, or ndjson")
+	blurhash := flag.Bool("blurhash", false, "compute a blurhash for each image (requires -format=json or -format=ndjson)")
+	phash := flag.Bool("phash", false, "compute a perceptual hash for each image (requires -format=json or -format=ndjson)")
+	flag.Parse()
+
+	if (*blurhash || *phash) && *format != string(batch.FormatJSON) && *format != string(batch.FormatNDJSON) {
+		fmt.Printf("Error: -blurhash/-phash require -format=json or -format=ndjson, got -format=%s\n", *format)
+		os.Exit(1)
+	}
+
 	// Get the default data folder path (project root/data)
 	// Use current working directory as base, then go up one level to find data folder
 	wd, err := os.Getwd()
 	if err != nil {
 		wd = "."
 	}
-	
+
 	// If we're in goimage folder, go up one level to find data
 	scriptDir := filepath.Base(wd)
 	var defaultDataPath string
@@ -208,8 +104,8 @@ func main() {
 	}
 
 	dataDir := defaultDataPath
-	if len(os.Args) > 1 {
-		dataDir = os.Args[1]
+	if flag.NArg() > 0 {
+		dataDir = flag.Arg(0)
 	}
 
 	// Resolve absolute path
@@ -219,9 +115,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := processImages(absPath); err != nil {
+	opts := detect.DetectOptions{
+		ComputeBlurhash: *blurhash,
+		ComputeHash:     *phash,
+	}
+	if err := processImages(absPath, *workers, batch.Format(*format), opts); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 }
-
@@ -1,24 +1,25 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/davidbyttow/govips/v2/vips"
+	"github.com/zihao-lin-appier/image-capture-result-error-detect/pkg/batch"
 )
 
-type ImageInfo struct {
-	Type   string
-	Width  int
-	Height int
-}
-
-func detectImageType(filePath string) (*ImageInfo, error) {
+// detectImageType decodes filePath with libvips and classifies it. Unlike
+// pkg/detect's Detect, this works directly on the raw grayscale byte
+// buffer vips hands back rather than an image.Image, since decoding
+// through libvips is the whole point of this variant.
+func detectImageType(filePath string) (batch.Info, error) {
 	img, err := vips.NewImageFromFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("error loading image: %v", err)
+		return batch.Info{}, fmt.Errorf("error loading image: %v", err)
 	}
 	defer img.Close()
 
@@ -29,23 +30,23 @@ func detectImageType(filePath string) (*ImageInfo, error) {
 	// Convert to grayscale if needed
 	if img.Bands() > 1 {
 		if err := img.ToColorSpace(vips.InterpretationBW); err != nil {
-			return nil, fmt.Errorf("error converting to grayscale: %v", err)
+			return batch.Info{}, fmt.Errorf("error converting to grayscale: %v", err)
 		}
 	}
 
 	// Ensure image is in uint8 format for ToBytes()
 	if err := img.Cast(vips.BandFormatUchar); err != nil {
-		return nil, fmt.Errorf("error casting image format: %v", err)
+		return batch.Info{}, fmt.Errorf("error casting image format: %v", err)
 	}
 
 	// Get pixel data as bytes
 	data, err := img.ToBytes()
 	if err != nil {
-		return nil, fmt.Errorf("error getting pixel data: %v", err)
+		return batch.Info{}, fmt.Errorf("error getting pixel data: %v", err)
 	}
 
 	if len(data) == 0 {
-		return nil, fmt.Errorf("image has no pixel data")
+		return batch.Info{}, fmt.Errorf("image has no pixel data")
 	}
 
 	// Calculate min and max
@@ -73,39 +74,25 @@ func detectImageType(filePath string) (*ImageInfo, error) {
 		result = "Mixed pixels"
 	}
 
-	return &ImageInfo{
-		Type:   result,
-		Width:  width,
-		Height: height,
-	}, nil
+	return batch.Info{Type: result, Width: width, Height: height}, nil
 }
 
-func main() {
-	vips.Startup(nil)
-	defer vips.Shutdown()
-
-	dataDir := "../data"
-	if len(os.Args) > 1 {
-		dataDir = os.Args[1]
-	}
+// Supported image extensions
+var imageExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".bmp":  true,
+	".webp": true,
+	".tiff": true,
+	".tif":  true,
+}
 
-	// Read all files in the data directory
+func getImageFiles(dataDir string) ([]string, error) {
 	entries, err := os.ReadDir(dataDir)
 	if err != nil {
-		fmt.Printf("Error reading directory %s: %v\n", dataDir, err)
-		os.Exit(1)
-	}
-
-	// Supported image extensions
-	imageExts := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".gif":  true,
-		".bmp":  true,
-		".webp": true,
-		".tiff": true,
-		".tif":  true,
+		return nil, fmt.Errorf("error reading directory %s: %v", dataDir, err)
 	}
 
 	var imageFiles []string
@@ -117,21 +104,36 @@ func main() {
 			}
 		}
 	}
+	return imageFiles, nil
+}
+
+func main() {
+	workers := flag.Int("j", runtime.NumCPU(), "number of images to detect concurrently")
+	format := flag.String("format", "text", "output format: text, json, csv, or ndjson")
+	flag.Parse()
+
+	dataDir := "../data"
+	if flag.NArg() > 0 {
+		dataDir = flag.Arg(0)
+	}
+
+	vips.Startup(nil)
+	defer vips.Shutdown()
+
+	imageFiles, err := getImageFiles(dataDir)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	if len(imageFiles) == 0 {
 		fmt.Printf("No image files found in %s\n", dataDir)
 		os.Exit(1)
 	}
 
-	// Process each image file
-	fmt.Printf("Processing %d image file(s) from %s:\n\n", len(imageFiles), dataDir)
-	for _, filePath := range imageFiles {
-		info, err := detectImageType(filePath)
-		if err != nil {
-			fmt.Printf("%s: ERROR - %v\n", filepath.Base(filePath), err)
-		} else {
-			fmt.Printf("%s (%dx%d): %s\n", filepath.Base(filePath), info.Width, info.Height, info.Type)
-		}
+	results, summary := batch.Run(imageFiles, *workers, detectImageType)
+	if err := batch.Write(os.Stdout, batch.Format(*format), results, summary); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 }
-
@@ -0,0 +1,132 @@
+// Package batch runs image detection over many files concurrently and
+// summarizes the results, so the goimage and govips CLIs can share one
+// worker pool and output format instead of each reimplementing both.
+package batch
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DetectFunc classifies a single image file. The libvips-backed CLI's
+// detector has this signature as is; the pkg/detect-backed CLI wraps
+// detect.DetectFile in an adapter to fit it.
+type DetectFunc func(path string) (Info, error)
+
+// Info is what a DetectFunc reports for one file. Blurhash and PHash are
+// left at their zero values by detectors that don't compute them.
+type Info struct {
+	Type     string
+	Width    int
+	Height   int
+	Blurhash string
+	PHash    uint64
+}
+
+// Result is the outcome of detecting one file, including how long it took
+// and how large it was, which feed into Summary's aggregates.
+type Result struct {
+	Path string
+	Info
+	Err      error
+	Bytes    int64
+	Duration time.Duration
+}
+
+// Summary aggregates a batch Run across all of its Results.
+type Summary struct {
+	Total      int
+	Errors     int
+	Counts     map[string]int
+	TotalBytes int64
+	Elapsed    time.Duration
+	P50Decode  time.Duration
+	P95Decode  time.Duration
+}
+
+// Run detects every path in paths using up to workers goroutines and
+// returns one Result per path, in the same order as paths, along with the
+// aggregate Summary.
+func Run(paths []string, workers int, detect DetectFunc) ([]Result, Summary) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]Result, len(paths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = detectOne(paths[i], detect)
+			}
+		}()
+	}
+
+	start := time.Now()
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	return results, summarize(results, elapsed)
+}
+
+func detectOne(path string, detect DetectFunc) Result {
+	start := time.Now()
+	info, err := detect(path)
+	duration := time.Since(start)
+
+	var size int64
+	if stat, statErr := os.Stat(path); statErr == nil {
+		size = stat.Size()
+	}
+
+	return Result{
+		Path:     path,
+		Info:     info,
+		Err:      err,
+		Bytes:    size,
+		Duration: duration,
+	}
+}
+
+func summarize(results []Result, elapsed time.Duration) Summary {
+	s := Summary{
+		Total:   len(results),
+		Counts:  map[string]int{},
+		Elapsed: elapsed,
+	}
+
+	durations := make([]time.Duration, 0, len(results))
+	for _, r := range results {
+		if r.Err != nil {
+			s.Errors++
+			continue
+		}
+		s.Counts[r.Type]++
+		s.TotalBytes += r.Bytes
+		durations = append(durations, r.Duration)
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	s.P50Decode = percentile(durations, 0.50)
+	s.P95Decode = percentile(durations, 0.95)
+
+	return s
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
@@ -0,0 +1,34 @@
+package batch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRunAggregatesCountsAndErrors(t *testing.T) {
+	paths := []string{"a.png", "b.png", "c.png"}
+	detect := func(path string) (Info, error) {
+		if path == "b.png" {
+			return Info{}, errors.New("boom")
+		}
+		return Info{Type: "All black", Width: 4, Height: 4}, nil
+	}
+
+	results, summary := Run(paths, 2, detect)
+
+	if len(results) != len(paths) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(paths))
+	}
+	if summary.Total != 3 || summary.Errors != 1 {
+		t.Errorf("summary = %+v, want Total=3 Errors=1", summary)
+	}
+	if got := summary.Counts["All black"]; got != 2 {
+		t.Errorf("Counts[All black] = %d, want 2", got)
+	}
+}
+
+func TestPercentileEmpty(t *testing.T) {
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("percentile(nil) = %v, want 0", got)
+	}
+}
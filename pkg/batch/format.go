@@ -0,0 +1,161 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// Format selects how Write renders a batch's results.
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// jsonResult is the JSON/ndjson wire shape for a Result; Result.Err is an
+// error, which encoding/json can't marshal directly.
+type jsonResult struct {
+	Path     string  `json:"path"`
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	Type     string  `json:"type,omitempty"`
+	Blurhash string  `json:"blurhash,omitempty"`
+	PHash    uint64  `json:"phash,omitempty"`
+	Error    string  `json:"error,omitempty"`
+	Bytes    int64   `json:"bytes"`
+	DecodeMs float64 `json:"decode_ms"`
+}
+
+type jsonSummary struct {
+	Total      int            `json:"total"`
+	Errors     int            `json:"errors"`
+	Counts     map[string]int `json:"counts"`
+	TotalBytes int64          `json:"total_bytes"`
+	ElapsedMs  float64        `json:"elapsed_ms"`
+	P50Ms      float64        `json:"p50_decode_ms"`
+	P95Ms      float64        `json:"p95_decode_ms"`
+}
+
+func toJSONResult(r Result) jsonResult {
+	jr := jsonResult{
+		Path:     r.Path,
+		Width:    r.Width,
+		Height:   r.Height,
+		Type:     r.Type,
+		Blurhash: r.Blurhash,
+		PHash:    r.PHash,
+		Bytes:    r.Bytes,
+		DecodeMs: msOf(r.Duration),
+	}
+	if r.Err != nil {
+		jr.Error = r.Err.Error()
+	}
+	return jr
+}
+
+func toJSONSummary(s Summary) jsonSummary {
+	return jsonSummary{
+		Total:      s.Total,
+		Errors:     s.Errors,
+		Counts:     s.Counts,
+		TotalBytes: s.TotalBytes,
+		ElapsedMs:  msOf(s.Elapsed),
+		P50Ms:      msOf(s.P50Decode),
+		P95Ms:      msOf(s.P95Decode),
+	}
+}
+
+func msOf(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1e6
+}
+
+// Write renders results and their summary in the given format.
+func Write(w io.Writer, format Format, results []Result, summary Summary) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, results, summary)
+	case FormatNDJSON:
+		return writeNDJSON(w, results, summary)
+	case FormatCSV:
+		return writeCSV(w, results)
+	case FormatText, "":
+		return writeText(w, results, summary)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func writeText(w io.Writer, results []Result, summary Summary) error {
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(w, "Error processing %s: %v\n", filepath.Base(r.Path), r.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%s (%dx%d): %s\n", filepath.Base(r.Path), r.Width, r.Height, r.Type)
+	}
+	fmt.Fprintf(w, "Total processing time: %.2f milliseconds\n", msOf(summary.Elapsed))
+	return nil
+}
+
+func writeJSON(w io.Writer, results []Result, summary Summary) error {
+	out := struct {
+		Results []jsonResult `json:"results"`
+		Summary jsonSummary  `json:"summary"`
+	}{
+		Summary: toJSONSummary(summary),
+	}
+	for _, r := range results {
+		out.Results = append(out.Results, toJSONResult(r))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeNDJSON(w io.Writer, results []Result, summary Summary) error {
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		if err := enc.Encode(toJSONResult(r)); err != nil {
+			return err
+		}
+	}
+	return enc.Encode(struct {
+		Summary jsonSummary `json:"summary"`
+	}{Summary: toJSONSummary(summary)})
+}
+
+func writeCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	if err := cw.Write([]string{"path", "width", "height", "type", "error", "bytes", "decode_ms"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		errStr := ""
+		if r.Err != nil {
+			errStr = r.Err.Error()
+		}
+		record := []string{
+			r.Path,
+			fmt.Sprintf("%d", r.Width),
+			fmt.Sprintf("%d", r.Height),
+			r.Type,
+			errStr,
+			fmt.Sprintf("%d", r.Bytes),
+			fmt.Sprintf("%.3f", msOf(r.Duration)),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package detect
+
+import (
+	"image"
+
+	"github.com/buckket/go-blurhash"
+)
+
+// blurhashComponentsX/Y are the component counts passed to blurhash.Encode;
+// 4x3 is the library's common default, balancing string size against
+// how much detail the hash preserves.
+const (
+	blurhashComponentsX = 4
+	blurhashComponentsY = 3
+)
+
+// computeBlurhash runs as its own pass over img via blurhash.Encode, which
+// walks every pixel through img.At() once per basis-function component —
+// the same per-pixel interface dispatch that classify's tiled scanners
+// (scan.go) exist to avoid. Fusing blurhash's basis-function accumulation
+// and phash's resize+DCT into classify's single tiled pass was judged too
+// large an undertaking for this change (it would mean reimplementing both
+// algorithms against the tiled scan's internals rather than calling the
+// library/stdlib versions); ComputeBlurhash and ComputeHash should be left
+// off for latency-sensitive, high-resolution batch paths until that work
+// happens.
+func computeBlurhash(img image.Image) (string, error) {
+	return blurhash.Encode(blurhashComponentsX, blurhashComponentsY, img)
+}
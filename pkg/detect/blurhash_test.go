@@ -0,0 +1,36 @@
+package detect
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDetectComputesBlurhash(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 16), B: 128, A: 255})
+		}
+	}
+
+	info, err := Detect(img, DetectOptions{ComputeBlurhash: true})
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if info.Blurhash == "" {
+		t.Error("Detect() with ComputeBlurhash left Blurhash empty")
+	}
+}
+
+func TestDetectSkipsBlurhashByDefault(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+
+	info, err := Detect(img, DetectOptions{})
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if info.Blurhash != "" {
+		t.Errorf("Detect() without ComputeBlurhash set Blurhash = %q, want empty", info.Blurhash)
+	}
+}
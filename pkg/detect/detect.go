@@ -0,0 +1,198 @@
+// Package detect implements capture-error detection for images: deciding
+// whether an image is all black, all white, a single flat color, or has
+// normal mixed-pixel content.
+//
+// It is the library extracted from the goimage and govips command-line
+// tools so the same classification logic can be reused and tested on its
+// own, independent of how an image is decoded or where it came from.
+package detect
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// defaultMaxPixels bounds the size of image we will fully decode. Inputs
+// whose DecodeConfig dimensions exceed this are rejected before the
+// (potentially very large) pixel buffer is allocated.
+const defaultMaxPixels = 100_000_000 // 100 MP
+
+// maxConfigProbeBytes bounds how much of an input DetectReader will
+// buffer while looking for a decodable image.DecodeConfig header. Most
+// formats resolve well within sniffBytes; this only kicks in for
+// pathological cases like an unusually large EXIF/APP1 segment before a
+// JPEG's SOF marker. Keeping this bounded is what lets the pixel-budget
+// check reject an oversized BMP/TIFF-style input before its
+// (roughly-size-proportional) bytes are fully read into memory.
+const maxConfigProbeBytes = 8 * 1024 * 1024 // 8 MB
+
+// ImageInfo describes the outcome of detecting a single image.
+type ImageInfo struct {
+	Type   string
+	Width  int
+	Height int
+
+	// Blurhash is a compact string representation of img, suitable for
+	// logging alongside Type. Only set when DetectOptions.ComputeBlurhash
+	// is true.
+	Blurhash string
+
+	// PHash is a 64-bit DCT-based perceptual hash of img, useful for
+	// grouping visually identical images (e.g. the same capture error
+	// from different cameras). Only set when DetectOptions.ComputeHash
+	// is true.
+	PHash uint64
+}
+
+// DetectOptions controls optional detection behavior. The zero value
+// reproduces the original, EXIF-agnostic behavior.
+type DetectOptions struct {
+	// RespectEXIFOrientation rotates/flips the decoded image according to
+	// its EXIF orientation tag (if any) before Width/Height are reported
+	// and before the uniformity scan runs, so both match what a viewer
+	// would show rather than the raw, as-stored pixel buffer.
+	RespectEXIFOrientation bool
+
+	// MaxPixels overrides defaultMaxPixels as the Width*Height budget
+	// checked against image.DecodeConfig before the full image is
+	// decoded. Zero keeps the default.
+	MaxPixels int
+
+	// Tolerance enables near-uniform classification: if the dominant gray
+	// value's coverage is at least 1-Tolerance, the image is reported as
+	// "Near-all-black", "Near-all-white", or "Near-single-color" instead
+	// of "Mixed pixels". Zero (the default) keeps the strict, exact-match
+	// classification.
+	Tolerance float64
+
+	// ComputeBlurhash computes ImageInfo.Blurhash via a separate pass over
+	// img (see computeBlurhash). Off by default since it costs extra work
+	// the fast path doesn't otherwise do.
+	ComputeBlurhash bool
+
+	// ComputeHash computes ImageInfo.PHash via a separate pass over img
+	// (see computePHash). Off by default for the same reason as
+	// ComputeBlurhash.
+	ComputeHash bool
+}
+
+func (o DetectOptions) maxPixels() int {
+	if o.MaxPixels > 0 {
+		return o.MaxPixels
+	}
+	return defaultMaxPixels
+}
+
+// Detect classifies an already-decoded image. Since orientation can only
+// be recovered from the original encoded bytes, opts.RespectEXIFOrientation
+// has no effect here; use DetectFile or DetectReader for EXIF-aware
+// detection.
+func Detect(img image.Image, opts DetectOptions) (*ImageInfo, error) {
+	bounds := img.Bounds()
+	typ := classify(img, opts.Tolerance)
+
+	info := &ImageInfo{
+		Type:   typ,
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}
+
+	if opts.ComputeBlurhash {
+		if bh, err := computeBlurhash(img); err == nil {
+			info.Blurhash = bh
+		}
+	}
+	if opts.ComputeHash {
+		info.PHash = computePHash(img)
+	}
+
+	return info, nil
+}
+
+// DetectFile opens and classifies the image at path.
+func DetectFile(path string, opts DetectOptions) (*ImageInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %v", err)
+	}
+	defer file.Close()
+
+	return DetectReader(file, opts)
+}
+
+// DetectReader classifies the image read from r. It first reads a bounded
+// prefix of r and probes it with image.DecodeConfig, growing the prefix
+// only as far as maxConfigProbeBytes if needed, so an oversized input can
+// be rejected against opts.maxPixels() without ever buffering the whole
+// thing.
+func DetectReader(r io.Reader, opts DetectOptions) (*ImageInfo, error) {
+	probe, cfg, err := probeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if pixels, max := cfg.Width*cfg.Height, opts.maxPixels(); pixels > max {
+		return nil, fmt.Errorf("image is %d pixels, exceeds max of %d", pixels, max)
+	}
+
+	rest := io.MultiReader(bytes.NewReader(probe), r)
+
+	var decodeSrc io.Reader = rest
+	var exifBuf *bytes.Buffer
+	if opts.RespectEXIFOrientation {
+		exifBuf = &bytes.Buffer{}
+		decodeSrc = io.TeeReader(rest, exifBuf)
+	}
+
+	img, _, err := image.Decode(decodeSrc)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding image: %v", err)
+	}
+
+	if opts.RespectEXIFOrientation {
+		if orientation := readOrientation(exifBuf.Bytes()); orientation != 1 {
+			img = applyOrientation(img, orientation)
+		}
+	}
+
+	return Detect(img, opts)
+}
+
+// probeConfig reads a bounded, growing prefix of r until image.DecodeConfig
+// can parse it (or maxConfigProbeBytes is reached), and returns that prefix
+// alongside the decoded config so the caller can resume reading the rest
+// of the image without losing the bytes already consumed.
+func probeConfig(r io.Reader) ([]byte, image.Config, error) {
+	probe := make([]byte, sniffBytes)
+	n, err := io.ReadFull(r, probe)
+	probe = probe[:n]
+	atEOF := err == io.EOF || err == io.ErrUnexpectedEOF
+	if err != nil && !atEOF {
+		return nil, image.Config{}, fmt.Errorf("error reading image: %v", err)
+	}
+
+	cfg, _, cfgErr := image.DecodeConfig(bytes.NewReader(probe))
+	for cfgErr != nil && !atEOF && len(probe) < maxConfigProbeBytes {
+		grow := make([]byte, len(probe))
+		gn, gerr := io.ReadFull(r, grow)
+		probe = append(probe, grow[:gn]...)
+		atEOF = gerr == io.EOF || gerr == io.ErrUnexpectedEOF
+		if gerr != nil && !atEOF {
+			return nil, image.Config{}, fmt.Errorf("error reading image: %v", gerr)
+		}
+		cfg, _, cfgErr = image.DecodeConfig(bytes.NewReader(probe))
+	}
+	if cfgErr != nil {
+		return nil, image.Config{}, fmt.Errorf("error reading image config: %v", cfgErr)
+	}
+	return probe, cfg, nil
+}
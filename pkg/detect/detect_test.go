@@ -0,0 +1,85 @@
+package detect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+// pngIHDR builds a minimal valid PNG signature + IHDR chunk declaring the
+// given dimensions. image.DecodeConfig only needs to read this far to
+// report Width/Height, so it's enough to test the pixel-budget check
+// without a full (and, for a huge declared size, enormous) pixel buffer.
+func pngIHDR(width, height uint32) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{137, 80, 78, 71, 13, 10, 26, 10})
+
+	data := make([]byte, 13)
+	binary.BigEndian.PutUint32(data[0:4], width)
+	binary.BigEndian.PutUint32(data[4:8], height)
+	data[8] = 8  // bit depth
+	data[9] = 6  // color type: truecolor with alpha
+	data[10] = 0 // compression
+	data[11] = 0 // filter
+	data[12] = 0 // interlace
+
+	chunkType := []byte("IHDR")
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(data)))
+	buf.Write(lenBuf[:])
+	buf.Write(chunkType)
+	buf.Write(data)
+
+	crc := crc32.ChecksumIEEE(append(append([]byte{}, chunkType...), data...))
+	var crcBuf [4]byte
+	binary.BigEndian.PutUint32(crcBuf[:], crc)
+	buf.Write(crcBuf[:])
+
+	return buf.Bytes()
+}
+
+// zeroReader stands in for the (potentially huge) remainder of an
+// oversized file; it never returns EOF and counts every byte it hands
+// out, so the test can assert DetectReader bounds how much it reads
+// before rejecting the image on the pixel budget.
+type zeroReader struct{ read int64 }
+
+func (z *zeroReader) Read(p []byte) (int, error) {
+	z.read += int64(len(p))
+	return len(p), nil
+}
+
+func TestDetectReaderRejectsOversizeWithoutBufferingTheRest(t *testing.T) {
+	header := pngIHDR(50000, 50000) // 2.5 billion declared pixels
+	rest := &zeroReader{}
+	r := io.MultiReader(bytes.NewReader(header), rest)
+
+	_, err := DetectReader(r, DetectOptions{MaxPixels: 1_000_000})
+	if err == nil || !strings.Contains(err.Error(), "exceeds max") {
+		t.Fatalf("DetectReader() error = %v, want an 'exceeds max' error", err)
+	}
+	if rest.read > maxConfigProbeBytes {
+		t.Errorf("DetectReader read %d bytes past the header before rejecting, want at most %d (maxConfigProbeBytes)", rest.read, maxConfigProbeBytes)
+	}
+}
+
+func TestDetectReaderDecodesWithinBudget(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := DetectReader(&buf, DetectOptions{})
+	if err != nil {
+		t.Fatalf("DetectReader() error = %v", err)
+	}
+	if info.Width != 4 || info.Height != 4 {
+		t.Errorf("DetectReader() = %dx%d, want 4x4", info.Width, info.Height)
+	}
+}
@@ -0,0 +1,29 @@
+package detect
+
+import (
+	"bytes"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// readOrientation extracts the EXIF orientation tag (1-8, per the
+// EXIF/TIFF spec) from raw image bytes. It returns 1 (no transform
+// needed) if the image carries no EXIF data or no orientation tag, which
+// is the common case for PNG and GIF.
+func readOrientation(data []byte) int {
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 1
+	}
+
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return 1
+	}
+
+	orientation, err := tag.Int(0)
+	if err != nil {
+		return 1
+	}
+	return orientation
+}
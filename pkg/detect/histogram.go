@@ -0,0 +1,34 @@
+package detect
+
+import "fmt"
+
+// nearUniformResult turns a 256-bucket gray histogram into a near-uniform
+// classification when the dominant bucket's coverage meets 1-tolerance,
+// or "Mixed pixels" otherwise. The histogram itself is built by the
+// tiled/type-switched scanners in scan.go, via uniformity.observe.
+func nearUniformResult(hist *[256]int, total int, tolerance float64) string {
+	if total == 0 {
+		return "Mixed pixels"
+	}
+
+	dominant, count := 0, 0
+	for gray, n := range hist {
+		if n > count {
+			dominant, count = gray, n
+		}
+	}
+
+	coverage := float64(count) / float64(total)
+	if coverage < 1-tolerance {
+		return "Mixed pixels"
+	}
+
+	switch dominant {
+	case 0:
+		return "Near-all-black"
+	case 255:
+		return "Near-all-white"
+	default:
+		return fmt.Sprintf("Near-single-color (gray=%d, coverage=%.1f%%)", dominant, coverage*100)
+	}
+}
@@ -0,0 +1,33 @@
+package detect
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestClassifyTolerantNearAllBlack(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			img.SetRGBA(x, y, color.RGBA{A: 255})
+		}
+	}
+	img.SetRGBA(0, 0, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	if got := classify(img, 0.02); got != "Near-all-black" {
+		t.Errorf("classify() = %q, want %q", got, "Near-all-black")
+	}
+}
+
+func TestClassifyTolerantFallsBackToStrict(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{A: 255})
+	img.SetRGBA(1, 0, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	img.SetRGBA(0, 1, color.RGBA{A: 255})
+	img.SetRGBA(1, 1, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	if got := classify(img, 0.001); got != "Mixed pixels" {
+		t.Errorf("classify() = %q, want %q", got, "Mixed pixels")
+	}
+}
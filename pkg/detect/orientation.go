@@ -0,0 +1,31 @@
+package detect
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// applyOrientation returns img transformed according to the EXIF
+// orientation value (1-8), undoing whatever rotation or mirroring the
+// capturing device recorded so the result matches what a viewer displays.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	default:
+		return img
+	}
+}
@@ -0,0 +1,32 @@
+package detect
+
+import (
+	"image"
+	"testing"
+)
+
+func TestApplyOrientationSwapsDimensions(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 6, 4))
+
+	for _, orientation := range []int{5, 6, 7, 8} {
+		got := applyOrientation(img, orientation)
+		b := got.Bounds()
+		if b.Dx() != 4 || b.Dy() != 6 {
+			t.Errorf("orientation %d: got %dx%d, want 4x6", orientation, b.Dx(), b.Dy())
+		}
+	}
+}
+
+func TestApplyOrientationIdentity(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 6, 4))
+	got := applyOrientation(img, 1)
+	if got != image.Image(img) {
+		t.Errorf("orientation 1 should return img unchanged")
+	}
+}
+
+func TestReadOrientationNoEXIF(t *testing.T) {
+	if got := readOrientation([]byte("not an image")); got != 1 {
+		t.Errorf("readOrientation() = %d, want 1", got)
+	}
+}
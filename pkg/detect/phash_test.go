@@ -0,0 +1,38 @@
+package detect
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestComputePHashStableUnderFlatColor(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	b := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			a.SetRGBA(x, y, color.RGBA{R: 30, G: 30, B: 30, A: 255})
+			b.SetRGBA(x, y, color.RGBA{R: 30, G: 30, B: 30, A: 255})
+		}
+	}
+
+	if got, want := computePHash(a), computePHash(b); got != want {
+		t.Errorf("computePHash() mismatch for identical flat images: %x != %x", got, want)
+	}
+}
+
+func TestComputePHashDiffersForDifferentImages(t *testing.T) {
+	black := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	checkered := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if (x/8+y/8)%2 == 0 {
+				checkered.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+
+	if got := computePHash(black); got == computePHash(checkered) {
+		t.Errorf("computePHash() produced the same hash for a black image and a checkerboard")
+	}
+}
@@ -0,0 +1,266 @@
+package detect
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// tileRows is the number of scanline rows processed per tile. Tiling keeps
+// the hot loop operating on small, cache-friendly slices of Pix while still
+// allowing a short-circuit the instant a differing pixel is seen.
+const tileRows = 32
+
+// calculateGrayValue calculates the gray value (brightness) of an RGB color.
+// Uses the standard luminance formula: 0.299*R + 0.587*G + 0.114*B
+func calculateGrayValue(r, g, b uint32) int {
+	return int(0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8))
+}
+
+// normalizeGrayValue normalizes gray value to range 1-254 for single color images.
+func normalizeGrayValue(grayValue int) int {
+	if grayValue == 0 {
+		return 1
+	}
+	if grayValue == 255 {
+		return 254
+	}
+	return grayValue
+}
+
+// uniformity accumulates the running state needed to decide whether every
+// pixel seen so far is identical, or merely shares the same gray value.
+// When hist is non-nil it also tallies a gray histogram, which disables
+// the early short-circuit: near-uniform classification needs every pixel
+// to know the dominant bucket's coverage.
+type uniformity struct {
+	haveFirst   bool
+	firstR      uint32
+	firstG      uint32
+	firstB      uint32
+	firstGray   int
+	allSame     bool
+	allSameGray bool
+	hist        *[256]int
+	total       int
+}
+
+// newUniformity returns a uniformity accumulator. Pass tolerant=true to
+// also build a gray histogram for near-uniform classification.
+func newUniformity(tolerant bool) uniformity {
+	if !tolerant {
+		return uniformity{}
+	}
+	return uniformity{hist: &[256]int{}}
+}
+
+func (u *uniformity) observe(r, g, b uint32) (done bool) {
+	gray := calculateGrayValue(r, g, b)
+	if u.hist != nil {
+		u.hist[gray]++
+		u.total++
+	}
+
+	if !u.haveFirst {
+		u.haveFirst = true
+		u.firstR, u.firstG, u.firstB = r, g, b
+		u.firstGray = gray
+		u.allSame = true
+		u.allSameGray = true
+		return false
+	}
+
+	if r != u.firstR || g != u.firstG || b != u.firstB {
+		u.allSame = false
+	}
+	if gray != u.firstGray {
+		u.allSameGray = false
+	}
+
+	// A histogram needs every pixel, so only short-circuit when we're not
+	// building one.
+	return u.hist == nil && !u.allSame && !u.allSameGray
+}
+
+func (u *uniformity) result(tolerance float64) string {
+	if !u.haveFirst {
+		return "Mixed pixels"
+	}
+
+	r8, g8, b8 := u.firstR>>8, u.firstG>>8, u.firstB>>8
+	switch {
+	case u.allSame && r8 == 0 && g8 == 0 && b8 == 0:
+		return "All black"
+	case u.allSame && r8 == 255 && g8 == 255 && b8 == 255:
+		return "All white"
+	case u.allSame || u.allSameGray:
+		return fmt.Sprintf("Single color (gray value: %d)", normalizeGrayValue(u.firstGray))
+	}
+
+	if u.hist != nil {
+		return nearUniformResult(u.hist, u.total, tolerance)
+	}
+	return "Mixed pixels"
+}
+
+// classify scans img for uniformity, optionally relaxed by tolerance (see
+// DetectOptions.Tolerance; 0 keeps the strict, exact-match behavior).
+// Concrete image types produced by the standard decoders get a tiled fast
+// path that walks Pix directly instead of going through the per-pixel
+// image.Image.At interface dispatch; any other image.Image falls back to
+// the generic path.
+func classify(img image.Image, tolerance float64) string {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return "Mixed pixels"
+	}
+
+	switch px := img.(type) {
+	case *image.RGBA:
+		return classifyRGBA(px, tolerance)
+	case *image.NRGBA:
+		return classifyNRGBA(px, tolerance)
+	case *image.YCbCr:
+		return classifyYCbCr(px, tolerance)
+	case *image.Gray:
+		return classifyGray(px, tolerance)
+	case *image.Paletted:
+		return classifyPaletted(px, tolerance)
+	default:
+		return classifyGeneric(img, tolerance)
+	}
+}
+
+func classifyGeneric(img image.Image, tolerance float64) string {
+	bounds := img.Bounds()
+	u := newUniformity(tolerance > 0)
+	for tileY := bounds.Min.Y; tileY < bounds.Max.Y; tileY += tileRows {
+		tileMaxY := tileY + tileRows
+		if tileMaxY > bounds.Max.Y {
+			tileMaxY = bounds.Max.Y
+		}
+		for y := tileY; y < tileMaxY; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r, g, b, _ := img.At(x, y).RGBA()
+				if u.observe(r, g, b) {
+					return "Mixed pixels"
+				}
+			}
+		}
+	}
+	return u.result(tolerance)
+}
+
+func classifyRGBA(img *image.RGBA, tolerance float64) string {
+	bounds := img.Bounds()
+	u := newUniformity(tolerance > 0)
+	for tileY := bounds.Min.Y; tileY < bounds.Max.Y; tileY += tileRows {
+		tileMaxY := tileY + tileRows
+		if tileMaxY > bounds.Max.Y {
+			tileMaxY = bounds.Max.Y
+		}
+		for y := tileY; y < tileMaxY; y++ {
+			rowStart := (y - img.Rect.Min.Y) * img.Stride
+			row := img.Pix[rowStart : rowStart+(bounds.Dx())*4]
+			for i := 0; i < len(row); i += 4 {
+				r, g, b := uint32(row[i])*0x101, uint32(row[i+1])*0x101, uint32(row[i+2])*0x101
+				if u.observe(r, g, b) {
+					return "Mixed pixels"
+				}
+			}
+		}
+	}
+	return u.result(tolerance)
+}
+
+func classifyNRGBA(img *image.NRGBA, tolerance float64) string {
+	bounds := img.Bounds()
+	u := newUniformity(tolerance > 0)
+	for tileY := bounds.Min.Y; tileY < bounds.Max.Y; tileY += tileRows {
+		tileMaxY := tileY + tileRows
+		if tileMaxY > bounds.Max.Y {
+			tileMaxY = bounds.Max.Y
+		}
+		for y := tileY; y < tileMaxY; y++ {
+			rowStart := (y - img.Rect.Min.Y) * img.Stride
+			row := img.Pix[rowStart : rowStart+(bounds.Dx())*4]
+			for i := 0; i < len(row); i += 4 {
+				// NRGBA is non-alpha-premultiplied; convert via the color
+				// package so RGBA() semantics match the generic path.
+				c := color.NRGBA{R: row[i], G: row[i+1], B: row[i+2], A: row[i+3]}
+				r, g, b, _ := c.RGBA()
+				if u.observe(r, g, b) {
+					return "Mixed pixels"
+				}
+			}
+		}
+	}
+	return u.result(tolerance)
+}
+
+func classifyGray(img *image.Gray, tolerance float64) string {
+	bounds := img.Bounds()
+	u := newUniformity(tolerance > 0)
+	for tileY := bounds.Min.Y; tileY < bounds.Max.Y; tileY += tileRows {
+		tileMaxY := tileY + tileRows
+		if tileMaxY > bounds.Max.Y {
+			tileMaxY = bounds.Max.Y
+		}
+		for y := tileY; y < tileMaxY; y++ {
+			rowStart := (y - img.Rect.Min.Y) * img.Stride
+			row := img.Pix[rowStart : rowStart+bounds.Dx()]
+			for _, v := range row {
+				gray := uint32(v) * 0x101
+				if u.observe(gray, gray, gray) {
+					return "Mixed pixels"
+				}
+			}
+		}
+	}
+	return u.result(tolerance)
+}
+
+func classifyPaletted(img *image.Paletted, tolerance float64) string {
+	bounds := img.Bounds()
+	u := newUniformity(tolerance > 0)
+	for tileY := bounds.Min.Y; tileY < bounds.Max.Y; tileY += tileRows {
+		tileMaxY := tileY + tileRows
+		if tileMaxY > bounds.Max.Y {
+			tileMaxY = bounds.Max.Y
+		}
+		for y := tileY; y < tileMaxY; y++ {
+			rowStart := (y - img.Rect.Min.Y) * img.Stride
+			row := img.Pix[rowStart : rowStart+bounds.Dx()]
+			for _, idx := range row {
+				r, g, b, _ := img.Palette[idx].RGBA()
+				if u.observe(r, g, b) {
+					return "Mixed pixels"
+				}
+			}
+		}
+	}
+	return u.result(tolerance)
+}
+
+func classifyYCbCr(img *image.YCbCr, tolerance float64) string {
+	bounds := img.Bounds()
+	u := newUniformity(tolerance > 0)
+	for tileY := bounds.Min.Y; tileY < bounds.Max.Y; tileY += tileRows {
+		tileMaxY := tileY + tileRows
+		if tileMaxY > bounds.Max.Y {
+			tileMaxY = bounds.Max.Y
+		}
+		for y := tileY; y < tileMaxY; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				yi := img.YOffset(x, y)
+				ci := img.COffset(x, y)
+				r8, g8, b8 := color.YCbCrToRGB(img.Y[yi], img.Cb[ci], img.Cr[ci])
+				r, g, b := uint32(r8)*0x101, uint32(g8)*0x101, uint32(b8)*0x101
+				if u.observe(r, g, b) {
+					return "Mixed pixels"
+				}
+			}
+		}
+	}
+	return u.result(tolerance)
+}
@@ -0,0 +1,64 @@
+package detect
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// classifyNaive is the original img.At()-per-pixel loop, kept here only as
+// a benchmark baseline for the tiled, type-switched scanner.
+func classifyNaive(img image.Image) string {
+	bounds := img.Bounds()
+	var u uniformity
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if u.observe(r, g, b) {
+				return "Mixed pixels"
+			}
+		}
+	}
+	return u.result(0)
+}
+
+func benchImage4K() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 3840, 2160))
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			img.Set(x, y, color.RGBA{R: 10, G: 10, B: 10, A: 255})
+		}
+	}
+	// A single differing pixel near the end forces both paths to walk
+	// almost the entire buffer, which is the worst case for each.
+	img.Set(img.Rect.Dx()-1, img.Rect.Dy()-1, color.RGBA{R: 200, G: 0, B: 0, A: 255})
+	return img
+}
+
+func BenchmarkClassifyNaive4K(b *testing.B) {
+	img := benchImage4K()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		classifyNaive(img)
+	}
+}
+
+func BenchmarkClassifyTiled4K(b *testing.B) {
+	img := benchImage4K()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		classifyRGBA(img, 0)
+	}
+}
+
+// BenchmarkClassifyTolerant4K measures the tiled, histogram-building path
+// used when DetectOptions.Tolerance > 0. It can't short-circuit (it needs
+// every pixel for the histogram), but it should still beat the naive
+// img.At() loop by staying on the tiled/type-switched path.
+func BenchmarkClassifyTolerant4K(b *testing.B) {
+	img := benchImage4K()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		classifyRGBA(img, 0.02)
+	}
+}
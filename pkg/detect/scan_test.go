@@ -0,0 +1,48 @@
+package detect
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestClassifyAllBlack(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	if got := classify(img, 0); got != "All black" {
+		t.Errorf("classify() = %q, want %q", got, "All black")
+	}
+}
+
+func TestClassifyAllWhite(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	if got := classify(img, 0); got != "All white" {
+		t.Errorf("classify() = %q, want %q", got, "All white")
+	}
+}
+
+func TestClassifyMixedPixels(t *testing.T) {
+	img := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{
+		color.Black, color.White,
+	})
+	img.SetColorIndex(1, 1, 1)
+	if got := classify(img, 0); got != "Mixed pixels" {
+		t.Errorf("classify() = %q, want %q", got, "Mixed pixels")
+	}
+}
+
+func TestClassifyMatchesNaive(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 120, G: 120, B: 120, A: 255})
+		}
+	}
+	img.SetNRGBA(3, 3, color.NRGBA{R: 0, G: 0, B: 0, A: 255})
+
+	if got, want := classify(img, 0), classifyNaive(img); got != want {
+		t.Errorf("classify() = %q, classifyNaive() = %q", got, want)
+	}
+}
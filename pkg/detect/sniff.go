@@ -0,0 +1,49 @@
+package detect
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"os"
+)
+
+// sniffBytes is how much of a file is read for the first probe pass.
+// 64KB is generous enough to walk past a typical camera JPEG's
+// EXIF/APP1 segment (which can carry an embedded thumbnail) to reach the
+// SOF marker image.DecodeConfig needs.
+const sniffBytes = 64 * 1024
+
+// SniffFile reports whether path looks like a decodable image by calling
+// image.DecodeConfig on it, rather than trusting its file extension. This
+// lets callers pick up renamed or extension-less image files and skip
+// anything that isn't actually an image before a caller tries to fully
+// decode it.
+//
+// It first tries a bounded read of sniffBytes, which is enough for the
+// vast majority of files; if that's too short (e.g. an unusually large
+// EXIF segment before the SOF marker), it rewinds and runs
+// image.DecodeConfig on the file itself, with no size bound, rather than
+// silently reporting the image as undecodable.
+func SniffFile(path string) (format string, ok bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	buf := make([]byte, sniffBytes)
+	n, _ := io.ReadFull(file, buf)
+
+	if _, format, err := image.DecodeConfig(bytes.NewReader(buf[:n])); err == nil {
+		return format, true
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", false
+	}
+	_, format, err = image.DecodeConfig(file)
+	if err != nil {
+		return "", false
+	}
+	return format, true
+}
@@ -0,0 +1,35 @@
+package detect
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSniffFile(t *testing.T) {
+	dir := t.TempDir()
+
+	imgPath := filepath.Join(dir, "capture.bin") // no image extension
+	f, err := os.Create(imgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if format, ok := SniffFile(imgPath); !ok || format != "png" {
+		t.Errorf("SniffFile(%q) = (%q, %v), want (\"png\", true)", imgPath, format, ok)
+	}
+
+	textPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(textPath, []byte("just some text"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := SniffFile(textPath); ok {
+		t.Errorf("SniffFile(%q) reported ok for a non-image file", textPath)
+	}
+}